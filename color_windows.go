@@ -0,0 +1,73 @@
+//go:build windows
+// +build windows
+
+package log
+
+import (
+	"os"
+	"os/signal"
+
+	"golang.org/x/sys/windows"
+)
+
+const enableVirtualTerminalProcessing = 0x0004
+
+var winConsoleModes = map[*os.File]uint32{}
+
+// On classic Windows consoles the \x1b[...m escapes the color type already
+// emits just render as garbage, since ENABLE_VIRTUAL_TERMINAL_PROCESSING is
+// off by default. Turn it on for stdout/stderr when they're real Windows
+// console handles, restoring the original mode on Ctrl-C/Ctrl-Break so the
+// console isn't left in a different state than we found it.
+func init() {
+	if NoColor {
+		return
+	}
+
+	ok := true
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		h := windows.Handle(f.Fd())
+
+		var mode uint32
+		if err := windows.GetConsoleMode(h, &mode); err != nil {
+			continue
+		}
+
+		if err := windows.SetConsoleMode(h, mode|enableVirtualTerminalProcessing); err != nil {
+			ok = false
+			continue
+		}
+		winConsoleModes[f] = mode
+	}
+
+	if !ok {
+		// Pre-Windows-10 consoles don't support the mode at all -- fall
+		// back to stripping the escapes rather than printing garbage.
+		NoColor = true
+		return
+	}
+
+	// Wire into this package's own Fatal/Panic exit paths, which call
+	// os.Exit/panic directly and would otherwise bypass the signal handler
+	// below entirely -- the single most common "process exit" case for a
+	// logging library.
+	preExit = RestoreWinConsoleModes
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		RestoreWinConsoleModes()
+		os.Exit(1)
+	}()
+}
+
+// RestoreWinConsoleModes restores stdout/stderr to the console mode they had
+// before this package's init turned on ENABLE_VIRTUAL_TERMINAL_PROCESSING.
+// Safe to call more than once. Callers that bypass this package's own
+// Fatal/Panic and call os.Exit themselves on Windows should defer this too.
+func RestoreWinConsoleModes() {
+	for f, mode := range winConsoleModes {
+		windows.SetConsoleMode(windows.Handle(f.Fd()), mode)
+	}
+}
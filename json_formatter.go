@@ -0,0 +1,84 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// A function that transforms a Field value before it is written into the
+// JSON object produced by JSONFormatter, keyed by Field.Key.
+type JSONFieldEncoder func(interface{}) interface{}
+
+// A JSON formatter, emitting one JSON object per Entry.
+//
+// Base fields (persistent, from WithField/WithFields) are written keyed by
+// Field.Key. Every other field is a call's own argument: it is folded into a
+// single "message" key -- via fmt.Sprintf against the Format field for a *f
+// call, or concatenated the same way the raw/text formatters do for a plain
+// Print/At call -- rather than also being written individually under its
+// synthetic FieldN key.
+type JSONFormatter struct {
+	Name            string
+	TimestampFormat string
+	FieldEncoders   map[string]JSONFieldEncoder
+}
+
+func MakeJSONFormatter(name string) Formatter {
+	return &JSONFormatter{
+		name,
+		time.RFC3339Nano,
+		make(map[string]JSONFieldEncoder),
+	}
+}
+
+// SetFieldEncoder registers a JSONFieldEncoder applied to the value of the
+// field with the given key before it is marshaled.
+func (j *JSONFormatter) SetFieldEncoder(key string, enc JSONFieldEncoder) {
+	j.FieldEncoders[key] = enc
+}
+
+func (j *JSONFormatter) Format(e Entry) ([]byte, error) {
+	timestampFormat := j.TimestampFormat
+	if timestampFormat == "" {
+		timestampFormat = time.RFC3339Nano
+	}
+
+	data := make(map[string]interface{})
+	data["level"] = e.EntryLevel().String()
+	data["time"] = time.Now().Format(timestampFormat)
+	data["name"] = j.Name
+
+	formattable, f, _ := formatTo(e.Fields())
+	var ff []interface{}
+	for _, fd := range e.Fields() {
+		switch {
+		case fd.Key == "Format":
+			continue
+		case fd.Base:
+			v := fd.Value
+			if enc, ok := j.FieldEncoders[fd.Key]; ok {
+				v = enc(v)
+			}
+			data[fd.Key] = v
+		default:
+			ff = append(ff, fd.Value)
+		}
+	}
+	if formattable {
+		data["message"] = fmt.Sprintf(f, ff...)
+	} else {
+		mb := &bytes.Buffer{}
+		for _, v := range ff {
+			fmt.Fprintf(mb, "%s", v)
+		}
+		data["message"] = mb.String()
+	}
+
+	b := &bytes.Buffer{}
+	if err := json.NewEncoder(b).Encode(data); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
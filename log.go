@@ -2,6 +2,7 @@ package log
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -57,6 +58,28 @@ type Logger interface {
 	Formatter
 	FormatterManager
 	Hooks
+	FieldLogger
+	FatalBypasser
+}
+
+// An interface for deriving child loggers carrying a persistent set of
+// fields, merged into every Entry the child subsequently builds.
+type FieldLogger interface {
+	WithField(key string, value interface{}) Logger
+	WithFields(fields ...Field) Logger
+}
+
+// An interface for bypassing the process-ending os.Exit(1)/panic(...) that
+// Fatal/Panic otherwise trigger, so the logger stays safe to use inside
+// libraries and unit tests. The bypass is local to the receiving logger --
+// it does not propagate to or from any parent/child derived via
+// WithField/WithFields. A bypassed entry is still emitted, prefixed with
+// "[FATAL BYPASSED]"/"[PANIC BYPASSED]" so downstream aggregators can flag it.
+type FatalBypasser interface {
+	NoFatals(bool)
+	NoPanics(bool)
+	WithNoFatals() Logger
+	WithNoPanics() Logger
 }
 
 type logger struct {
@@ -66,6 +89,11 @@ type logger struct {
 	formatters formatters
 	Hooks
 	sync.Mutex
+	fields []Field
+
+	bypassMu sync.Mutex
+	noFatals bool
+	noPanics bool
 }
 
 func New(w io.Writer, l Level, tag string) *logger {
@@ -83,8 +111,62 @@ func (l *logger) Level() Level {
 	return l.level
 }
 
+// mergeFields prepends the logger's persistent base fields to v, renumbering
+// v's Order so the base fields sort first.
+func (l *logger) mergeFields(v []Field) []Field {
+	if len(l.fields) == 0 {
+		return v
+	}
+	ret := make([]Field, 0, len(l.fields)+len(v))
+	ret = append(ret, l.fields...)
+	offset := len(l.fields)
+	for _, f := range v {
+		f.Order += offset
+		ret = append(ret, f)
+	}
+	return ret
+}
+
+//
+func (l *logger) WithField(key string, value interface{}) Logger {
+	return l.WithFields(Field{Key: key, Value: value})
+}
+
+//
+func (l *logger) WithFields(fields ...Field) Logger {
+	base := make([]Field, 0, len(l.fields)+len(fields))
+	base = append(base, l.fields...)
+	offset := len(l.fields)
+	for i, f := range fields {
+		f.Order = offset + i
+		f.Base = true
+		base = append(base, f)
+	}
+	return &logger{
+		Writer:     l.Writer,
+		level:      l.level,
+		Formatter:  l.Formatter,
+		formatters: l.formatters,
+		Hooks:      l.Hooks,
+		fields:     base,
+	}
+}
+
+// ErrDropEntry is returned by a PRE hook to signal that log should drop the
+// entry rather than format and write it -- used by SamplingHook to throttle
+// high-volume logging without treating the drop as a hook failure.
+var ErrDropEntry = errors.New("log: entry dropped by hook")
+
+// preExit runs immediately before Fatal/Panic's process-ending os.Exit(1)/
+// panic(...), on every platform. A no-op by default; on Windows
+// (color_windows.go) the virtual-terminal-processing init sets it to
+// RestoreWinConsoleModes once it has turned that console mode on.
+var preExit = func() {}
+
 func log(e Entry) {
-	fire(PRE, e.EntryLevel(), e)
+	if fire(PRE, e.EntryLevel(), e) == ErrDropEntry {
+		return
+	}
 	reader := read(e)
 	e.Lock()
 	copy(e, reader)
@@ -107,46 +189,105 @@ func copy(e Entry, r *bytes.Buffer) {
 	}
 }
 
-func fire(at Timing, lv Level, e Entry) {
-	if err := e.Fire(at, lv, e); err != nil {
+func fire(at Timing, lv Level, e Entry) error {
+	err := e.Fire(at, lv, e)
+	if err != nil && err != ErrDropEntry {
 		e.Lock()
 		fmt.Fprintf(os.Stdout, "log: Failed to fire hook -- %v\n", err)
 		e.Unlock()
 	}
+	return err
+}
+
+// isNoFatals and isNoPanics read this logger's own bypass flags, set by
+// NoFatals/NoPanics. They are deliberately local to l -- not shared with any
+// parent or sibling derived via WithField/WithFields -- so bypassing one
+// request-scoped child logger can't silently disable process-exit behavior
+// for the rest of the tree.
+func (l *logger) isNoFatals() bool {
+	l.bypassMu.Lock()
+	defer l.bypassMu.Unlock()
+	return l.noFatals
+}
+
+func (l *logger) isNoPanics() bool {
+	l.bypassMu.Lock()
+	defer l.bypassMu.Unlock()
+	return l.noPanics
 }
 
 //
 func (l *logger) Fatal(v ...interface{}) {
 	if l.level >= LFatal {
-		log(newEntry(l, LFatal, mkFields(0, v...)...))
+		bypass := l.isNoFatals()
+		if bypass {
+			v = append([]interface{}{"[FATAL BYPASSED] "}, v...)
+		}
+		log(newEntry(l, LFatal, l.mergeFields(mkFields(0, v...))...))
+		if !bypass {
+			preExit()
+			os.Exit(1)
+		}
 	}
 }
 
 //
 func (l *logger) Fatalf(format string, v ...interface{}) {
 	if l.level >= LFatal {
-		log(newEntry(l, LFatal, mkFormatFields(format, v...)...))
+		bypass := l.isNoFatals()
+		if bypass {
+			format = "[FATAL BYPASSED] " + format
+		}
+		log(newEntry(l, LFatal, l.mergeFields(mkFormatFields(format, v...))...))
+		if !bypass {
+			preExit()
+			os.Exit(1)
+		}
 	}
 }
 
 //
 func (l *logger) Fatalln(v ...interface{}) {
 	if l.level >= LFatal {
-		log(newEntry(l, LFatal, mkFields(0, v...)...))
+		bypass := l.isNoFatals()
+		if bypass {
+			v = append([]interface{}{"[FATAL BYPASSED] "}, v...)
+		}
+		log(newEntry(l, LFatal, l.mergeFields(mkFields(0, v...))...))
+		if !bypass {
+			preExit()
+			os.Exit(1)
+		}
 	}
 }
 
 //
 func (l *logger) Panic(v ...interface{}) {
 	if l.level >= LPanic {
-		log(newEntry(l, LPanic, mkFields(0, v...)...))
+		bypass := l.isNoPanics()
+		if bypass {
+			v = append([]interface{}{"[PANIC BYPASSED] "}, v...)
+		}
+		log(newEntry(l, LPanic, l.mergeFields(mkFields(0, v...))...))
+		if !bypass {
+			preExit()
+			panic("panic hook")
+		}
 	}
 }
 
 //
 func (l *logger) Panicf(format string, v ...interface{}) {
 	if l.level >= LPanic {
-		log(newEntry(l, LPanic, mkFormatFields(format, v...)...))
+		bypass := l.isNoPanics()
+		if bypass {
+			format = "[PANIC BYPASSED] " + format
+		}
+		log(newEntry(l, LPanic, l.mergeFields(mkFormatFields(format, v...))...))
+		if !bypass {
+			preExit()
+			panic("panic hook")
+		}
 	}
 }
 
@@ -155,41 +296,69 @@ func (l *logger) Panicln(v ...interface{}) {
 	l.Panic(v...)
 }
 
+//
+func (l *logger) NoFatals(v bool) {
+	l.bypassMu.Lock()
+	l.noFatals = v
+	l.bypassMu.Unlock()
+}
+
+//
+func (l *logger) NoPanics(v bool) {
+	l.bypassMu.Lock()
+	l.noPanics = v
+	l.bypassMu.Unlock()
+}
+
+//
+func (l *logger) WithNoFatals() Logger {
+	l.NoFatals(true)
+	return l
+}
+
+//
+func (l *logger) WithNoPanics() Logger {
+	l.NoPanics(true)
+	return l
+}
+
 //
 func (l *logger) Print(v ...interface{}) {
 	if l.level >= LError {
-		log(newEntry(l, LInfo, mkFields(0, v...)...))
+		log(newEntry(l, LInfo, l.mergeFields(mkFields(0, v...))...))
 	}
 }
 
 //
 func (l *logger) Printf(format string, v ...interface{}) {
 	if l.level >= LError {
-		log(newEntry(l, LInfo, mkFormatFields(format, v...)...))
+		log(newEntry(l, LInfo, l.mergeFields(mkFormatFields(format, v...))...))
 	}
 }
 
 //
 func (l *logger) Println(v ...interface{}) {
 	if l.level >= LError {
-		log(newEntry(l, LInfo, mkFields(0, v...)...))
+		log(newEntry(l, LInfo, l.mergeFields(mkFields(0, v...))...))
 	}
 }
 
 //
 func (l *logger) At(lv Level, v ...interface{}) {
-	log(newEntry(l, lv, mkFields(0, v...)...))
+	log(newEntry(l, lv, l.mergeFields(mkFields(0, v...))...))
 }
 
 //
 func (l *logger) Atf(lv Level, m string, v ...interface{}) {
-	log(newEntry(l, lv, mkFormatFields(m, v...)...))
+	log(newEntry(l, lv, l.mergeFields(mkFormatFields(m, v...))...))
 }
 
 //
 func (l *logger) AtTo(lv Level, to io.Writer, v ...interface{}) {
-	e := newEntry(l, lv, mkFields(0, v...)...)
-	fire(PRE, lv, e)
+	e := newEntry(l, lv, l.mergeFields(mkFields(0, v...))...)
+	if fire(PRE, lv, e) == ErrDropEntry {
+		return
+	}
 	reader, _ := e.Read()
 	io.Copy(to, reader)
 	fire(POST, lv, e)
@@ -197,8 +366,10 @@ func (l *logger) AtTo(lv Level, to io.Writer, v ...interface{}) {
 
 //
 func (l *logger) AtTof(lv Level, to io.Writer, m string, v ...interface{}) {
-	e := newEntry(l, lv, mkFormatFields(m, v...)...)
-	fire(PRE, lv, e)
+	e := newEntry(l, lv, l.mergeFields(mkFormatFields(m, v...))...)
+	if fire(PRE, lv, e) == ErrDropEntry {
+		return
+	}
 	reader, _ := e.Read()
 	io.Copy(to, reader)
 	fire(POST, lv, e)
@@ -271,10 +442,7 @@ func newHooks() *hooks {
 	has := make(map[Timing]map[Level][]Hook)
 	has[PRE] = make(map[Level][]Hook)
 	has[POST] = make(map[Level][]Hook)
-	h := &hooks{has}
-	h.AddHook(POST, LFatal, hookFor(func(Entry) error { os.Exit(1); return nil }))
-	h.AddHook(POST, LPanic, hookFor(func(Entry) error { panic("panic hook"); return nil }))
-	return h
+	return &hooks{has: has}
 }
 
 //
@@ -317,20 +485,26 @@ type Field struct {
 	Order int
 	Key   string
 	Value interface{}
+
+	// Base marks a persistent field attached via WithField/WithFields, as
+	// opposed to a field built from a single call's own arguments. Formatters
+	// must not treat Base fields as positional fmt.Sprintf args for *f calls --
+	// only the call's own fields fill the Format verbs.
+	Base bool
 }
 
 func mkFields(index int, v ...interface{}) []Field {
 	var ret []Field
 	for i, vv := range v {
 		idx := i + index
-		ret = append(ret, Field{idx, fmt.Sprintf("Field%d", idx), vv})
+		ret = append(ret, Field{Order: idx, Key: fmt.Sprintf("Field%d", idx), Value: vv})
 	}
 	return ret
 }
 
 func mkFormatFields(format string, v ...interface{}) []Field {
 	var ret []Field
-	ret = append(ret, Field{1, "Format", format})
+	ret = append(ret, Field{Order: 1, Key: "Format", Value: format})
 	ret = append(ret, mkFields(1, v...)...)
 	return ret
 }
@@ -493,6 +667,7 @@ func defaultFormatters(tag string) formatters {
 		"null": DefaultNullFormatter(),
 		"raw":  DefaultRawFormatter(),
 		"text": MakeTextFormatter(tag),
+		"json": MakeJSONFormatter(tag),
 	}
 }
 
@@ -619,17 +794,34 @@ func (t *TextFormatter) formatFields(b *bytes.Buffer, e Entry, keys []string, ti
 	format(b, fds)
 }
 
+// formatTo splits fds into the *f call's format string (if any) and its
+// positional arguments. Base fields (persistent, from WithField/WithFields)
+// are never positional arguments for a format string -- they're excluded
+// from ff once a Format field is present, so a WithField-derived logger's
+// persistent values can't be consumed by the caller's own %verbs. For a
+// non-formatted call (no Format field), Base fields fall back to
+// contributing to ff like any other field, matching the plain Print/At
+// concatenation every field (persistent or not) has always produced.
 func formatTo(fds []Field) (bool, string, []interface{}) {
 	var formattable bool
 	var f string
-	var ff []interface{}
 	for _, fd := range fds {
 		if fd.Key == "Format" {
 			formattable = true
 			f = fd.Value.(string)
-		} else {
-			ff = append(ff, fd.Value)
+			break
+		}
+	}
+
+	var ff []interface{}
+	for _, fd := range fds {
+		if fd.Key == "Format" {
+			continue
+		}
+		if formattable && fd.Base {
+			continue
 		}
+		ff = append(ff, fd.Value)
 	}
 	return formattable, f, ff
 }
@@ -639,6 +831,11 @@ func format(b *bytes.Buffer, fds FieldsSort) {
 	formattable, f, ff := formatTo(fds)
 	if formattable {
 		fmt.Fprintf(b, f, ff...)
+		for _, fd := range fds {
+			if fd.Base {
+				fmt.Fprintf(b, " %s=%v", fd.Key, fd.Value)
+			}
+		}
 	} else {
 		for _, v := range ff {
 			fmt.Fprintf(b, "%s", v)
@@ -2,11 +2,18 @@ package log
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func errOut(t *testing.T, w, m string) {
@@ -29,9 +36,9 @@ func TestEntry(t *testing.T) {
 	l := New(b, LDebug, "TEST")
 	l.SwapFormatter("raw")
 	f := []Field{
-		{0, "one", "first field-"},
-		{1, "two", "second field-"},
-		{2, "three", "third field"},
+		{0, "one", "first field-", false},
+		{1, "two", "second field-", false},
+		{2, "three", "third field", false},
 	}
 	e := newEntry(l, LDebug, f...)
 	r, err := e.Read()
@@ -68,6 +75,7 @@ var fmtrs []fmtr = []fmtr{
 	{"null", "", []string{""}, []string{"MESSAGE", "DEBUG"}},
 	{"raw", "MESSAGE", []string{"MESSAGE"}, []string{"DEBUG"}},
 	{"text", "MESSAGE", []string{"INFO", "TEST"}, []string{"PANIC"}},
+	{"json", "MESSAGE", []string{"MESSAGE", "TEST"}, []string{"PANIC"}},
 }
 
 func contains(s string, exp []string) bool {
@@ -123,6 +131,234 @@ func TestHook(t *testing.T) {
 	}
 }
 
+func TestWithFields(t *testing.T) {
+	b := new(bytes.Buffer)
+	l := New(b, LDebug, "TEST")
+	l.SwapFormatter("raw")
+
+	cl := l.WithField("one", "first-")
+	cl = cl.WithFields(Field{Key: "two", Value: "second-"})
+	cl.Print("third")
+
+	exp := "first-second-third\n"
+	if res := b.String(); res != exp {
+		errOut(t, "WithFields", fmt.Sprintf("got %s, but expected %s", res, exp))
+	}
+}
+
+func TestWithFieldsPrintf(t *testing.T) {
+	b := new(bytes.Buffer)
+	l := New(b, LDebug, "TEST")
+	l.SwapFormatter("raw")
+
+	cl := l.WithField("request_id", "abc123")
+	cl.Printf("processed %d items in %s", 42, "2s")
+
+	exp := "processed 42 items in 2s request_id=abc123\n"
+	if res := b.String(); res != exp {
+		errOut(t, "WithFieldsPrintf", fmt.Sprintf("got %s, but expected %s", res, exp))
+	}
+}
+
+func TestJSONFormatterFields(t *testing.T) {
+	b := new(bytes.Buffer)
+	l := New(b, LDebug, "TEST")
+	l.SwapFormatter("json")
+
+	cl := l.WithField("request_id", "abc123")
+	cl.Printf("processed %d items in %s", 42, "2s")
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &data); err != nil {
+		errOut(t, "JSONFormatterFields", err.Error())
+	}
+
+	if data["message"] != "processed 42 items in 2s" {
+		errOut(t, "JSONFormatterFields", fmt.Sprintf("message is %v", data["message"]))
+	}
+	if data["request_id"] != "abc123" {
+		errOut(t, "JSONFormatterFields", fmt.Sprintf("request_id is %v", data["request_id"]))
+	}
+	if _, ok := data["Field1"]; ok {
+		errOut(t, "JSONFormatterFields", "Field1 should not be emitted, its value is already folded into message")
+	}
+
+	b.Reset()
+	l.Print("plain message")
+	data = nil
+	if err := json.Unmarshal(b.Bytes(), &data); err != nil {
+		errOut(t, "JSONFormatterFields", err.Error())
+	}
+	if data["message"] != "plain message" {
+		errOut(t, "JSONFormatterFields", fmt.Sprintf("message is %v, expected plain message", data["message"]))
+	}
+}
+
+func TestMetricsHook(t *testing.T) {
+	b := new(bytes.Buffer)
+	l := New(b, LDebug, "TEST")
+	reg := prometheus.NewRegistry()
+	h := NewMetricsHook(reg)
+	RegisterMetricsHook(l, h)
+
+	l.Print("RUN")
+	l.At(LWarn, "RUN")
+
+	if c := testutil.ToFloat64(h.entries.WithLabelValues("info")); c != 1 {
+		errOut(t, "MetricsHook", fmt.Sprintf("info count is %v, expected 1", c))
+	}
+	if c := testutil.ToFloat64(h.entries.WithLabelValues("warn")); c != 1 {
+		errOut(t, "MetricsHook", fmt.Sprintf("warn count is %v, expected 1", c))
+	}
+}
+
+func TestNoFatals(t *testing.T) {
+	b := new(bytes.Buffer)
+	l := New(b, LFatal, "TEST").WithNoFatals()
+	l.SwapFormatter("raw")
+	l.Fatal("FATAL!")
+	if !strings.Contains(b.String(), "[FATAL BYPASSED]") {
+		errOut(t, "NoFatals", fmt.Sprintf("expected bypass prefix in %s", b.String()))
+	}
+}
+
+func TestNoPanics(t *testing.T) {
+	b := new(bytes.Buffer)
+	l := New(b, LPanic, "TEST").WithNoPanics()
+	l.SwapFormatter("raw")
+	l.Panic("PANIC!")
+	if !strings.Contains(b.String(), "[PANIC BYPASSED]") {
+		errOut(t, "NoPanics", fmt.Sprintf("expected bypass prefix in %s", b.String()))
+	}
+}
+
+func TestSamplingHook(t *testing.T) {
+	q := newQuantileSketch(0.01)
+	for i := 1; i <= 100; i++ {
+		q.Insert(float64(i))
+	}
+	if p99 := q.Query(0.99); p99 < 90 || p99 > 100 {
+		errOut(t, "SamplingHook", fmt.Sprintf("p99 is %v, expected close to 100", p99))
+	}
+
+	b := new(bytes.Buffer)
+	l := New(b, LDebug, "TEST")
+	l.SwapFormatter("raw")
+	h := NewSamplingHook(10)
+	RegisterSamplingHook(l, h)
+
+	// Establish a baseline inter-arrival rate so the sketch has a p99 to
+	// throttle against.
+	for i := 0; i < 20; i++ {
+		l.Print("MESSAGE")
+		time.Sleep(5 * time.Millisecond)
+	}
+	if b.Len() == 0 {
+		errOut(t, "SamplingHook", "expected baseline entries to be written")
+	}
+
+	// Hammer it back-to-back, far faster than the baseline rate -- entries
+	// should now get dropped rather than all sailing through.
+	b.Reset()
+	const burst = 50
+	for i := 0; i < burst; i++ {
+		l.Print("MESSAGE")
+	}
+	if written := strings.Count(b.String(), "MESSAGE"); written >= burst {
+		errOut(t, "SamplingHook", fmt.Sprintf("expected some of %d entries dropped, got all %d written", burst, written))
+	}
+}
+
+func TestRotatingWriter(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/test.log"
+
+	w, err := NewRotatingWriter(path, 10, 0, 1)
+	if err != nil {
+		errOut(t, "RotatingWriter", err.Error())
+		return
+	}
+	defer w.Close()
+
+	for i := 0; i < 5; i++ {
+		if _, err := w.Write([]byte("0123456789")); err != nil {
+			errOut(t, "RotatingWriter", err.Error())
+		}
+	}
+
+	matches, _ := filepath.Glob(dir + "/test.*.log.gz")
+	if len(matches) == 0 {
+		errOut(t, "RotatingWriter", "expected at least one rotated backup")
+	}
+	if len(matches) > 1 {
+		errOut(t, "RotatingWriter", fmt.Sprintf("expected MaxBackups to prune to 1, got %d", len(matches)))
+	}
+
+	if err := w.Reopen(); err != nil {
+		errOut(t, "RotatingWriter", err.Error())
+	}
+	if _, err := os.Stat(path); err != nil {
+		errOut(t, "RotatingWriter", fmt.Sprintf("expected %s to exist after Reopen: %v", path, err))
+	}
+}
+
+// TestRotatingWriterSameSecondRotations guards against backup filenames
+// colliding (and silently overwriting each other via gzipFile's O_TRUNC) when
+// several rotations happen within the same wall-clock second, which a
+// MaxBackups=1 test like TestRotatingWriter can't distinguish from correct
+// pruning.
+func TestRotatingWriterSameSecondRotations(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/test.log"
+
+	w, err := NewRotatingWriter(path, 10, 0, 5)
+	if err != nil {
+		errOut(t, "RotatingWriterSameSecondRotations", err.Error())
+		return
+	}
+	defer w.Close()
+
+	for i := 0; i < 20; i++ {
+		if _, err := w.Write([]byte("0123456789")); err != nil {
+			errOut(t, "RotatingWriterSameSecondRotations", err.Error())
+		}
+	}
+
+	matches, _ := filepath.Glob(dir + "/test.*.log.gz")
+	if len(matches) != w.MaxBackups {
+		errOut(t, "RotatingWriterSameSecondRotations", fmt.Sprintf("expected MaxBackups (%d) backups on disk, got %d -- same-second rotations may be overwriting each other", w.MaxBackups, len(matches)))
+	}
+}
+
+func TestNoFatalsDoesNotLeakToParent(t *testing.T) {
+	testFatal(t, "TestNoFatalsDoesNotLeakToParent", func(l Logger) {
+		child := l.WithField("request_id", "abc").WithNoFatals()
+		child.Fatal("bypassed")
+		l.Fatal("not bypassed")
+	})
+}
+
+func TestNoPanicsDoesNotLeakToParent(t *testing.T) {
+	testPanic(t, func(l Logger) {
+		child := l.WithField("request_id", "abc").WithNoPanics()
+		child.Panic("bypassed")
+		l.Panic("not bypassed")
+	})
+}
+
+func TestNoFatalsNoPanicsRace(t *testing.T) {
+	l := New(new(bytes.Buffer), LDebug, "TEST")
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(4)
+		go func() { defer wg.Done(); l.NoFatals(true) }()
+		go func() { defer wg.Done(); l.NoFatals(false) }()
+		go func() { defer wg.Done(); l.NoPanics(true) }()
+		go func() { defer wg.Done(); l.NoPanics(false) }()
+	}
+	wg.Wait()
+}
+
 func probe(t *testing.T, tag, exp string, fn func(), b *bytes.Buffer) {
 	fn()
 	res := b.String()
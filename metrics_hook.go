@@ -0,0 +1,38 @@
+package log
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// A Hook that counts log entries fired per Level, exposed as a Prometheus
+// counter vector for ops visibility into logging rates and error spikes.
+type MetricsHook struct {
+	entries *prometheus.CounterVec
+}
+
+func NewMetricsHook(reg prometheus.Registerer) *MetricsHook {
+	h := &MetricsHook{
+		entries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "log_entries_total",
+				Help: "Total number of log entries fired, by level.",
+			},
+			[]string{"level"},
+		),
+	}
+	reg.MustRegister(h.entries)
+	return h
+}
+
+func (h *MetricsHook) Fire(e Entry) error {
+	h.entries.WithLabelValues(e.EntryLevel().String()).Inc()
+	return nil
+}
+
+// RegisterMetricsHook adds h as a POST hook across every Level, so it counts
+// all log entries without needing one AddHook call per Level.
+func RegisterMetricsHook(l Logger, h *MetricsHook) {
+	for _, lv := range Levels {
+		l.AddHook(POST, lv, h)
+	}
+}
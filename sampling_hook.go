@@ -0,0 +1,166 @@
+package log
+
+import (
+	"sync"
+	"time"
+)
+
+// A single tuple in a CKMS biased-quantile summary, as described in Cormode,
+// Korn, Muthukrishnan & Srivastava's "Effective Computation of Biased
+// Quantiles over Data Streams" (the approach bmizerany/perks/quantile
+// implements). g is the number of values between this tuple and the
+// previous one; delta bounds the uncertainty in that count.
+type quantileSample struct {
+	value float64
+	g     int
+	delta int
+}
+
+// A bounded-size streaming quantile sketch tracking inter-arrival durations.
+type quantileSketch struct {
+	mu      sync.Mutex
+	epsilon float64
+	n       int
+	samples []quantileSample
+}
+
+func newQuantileSketch(epsilon float64) *quantileSketch {
+	return &quantileSketch{epsilon: epsilon}
+}
+
+// invariant is the biased error function f(r, n) = 2*epsilon*r.
+func (q *quantileSketch) invariant(r int) int {
+	return int(2 * q.epsilon * float64(r))
+}
+
+func (q *quantileSketch) Insert(v float64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i, r := 0, 0
+	for i < len(q.samples) && q.samples[i].value < v {
+		r += q.samples[i].g
+		i++
+	}
+
+	delta := 0
+	if i > 0 && i < len(q.samples) {
+		delta = q.invariant(r) - 1
+		if delta < 0 {
+			delta = 0
+		}
+	}
+
+	q.samples = append(q.samples, quantileSample{})
+	for j := len(q.samples) - 1; j > i; j-- {
+		q.samples[j] = q.samples[j-1]
+	}
+	q.samples[i] = quantileSample{value: v, g: 1, delta: delta}
+	q.n++
+
+	if q.epsilon > 0 && q.n%int(1/(2*q.epsilon)) == 0 {
+		q.compress()
+	}
+}
+
+// compress merges adjacent tuples whose combined weight still satisfies the
+// invariant, keeping the sketch's memory bounded.
+func (q *quantileSketch) compress() {
+	r := 0
+	for i := 0; i < len(q.samples)-1; i++ {
+		cur, next := q.samples[i], q.samples[i+1]
+		if cur.g+next.g+next.delta <= q.invariant(r) {
+			next.g += cur.g
+			q.samples[i+1] = next
+			q.samples = append(q.samples[:i], q.samples[i+1:]...)
+			i--
+			continue
+		}
+		r += cur.g
+	}
+}
+
+// Query returns the current phi-quantile (0 <= phi <= 1) tracked by the
+// sketch, or 0 if no values have been inserted yet.
+func (q *quantileSketch) Query(phi float64) float64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.samples) == 0 {
+		return 0
+	}
+
+	rank := int(phi * float64(q.n))
+	threshold := rank + q.invariant(rank)/2
+
+	r := 0
+	for _, s := range q.samples {
+		r += s.g
+		if r+s.delta > threshold {
+			return s.value
+		}
+	}
+	return q.samples[len(q.samples)-1].value
+}
+
+// A Hook, fired PRE, that throttles high-volume logging. It tracks the p99
+// inter-arrival duration per Level in a quantileSketch and drops an entry
+// (via ErrDropEntry) when its rate exceeds that p99 by Factor.
+type SamplingHook struct {
+	Factor float64
+
+	mu        sync.Mutex
+	lastFired map[Level]time.Time
+	sketches  map[Level]*quantileSketch
+}
+
+const samplingEpsilon = 0.01
+
+func NewSamplingHook(factor float64) *SamplingHook {
+	sketches := make(map[Level]*quantileSketch, len(Levels))
+	for _, lv := range Levels {
+		sketches[lv] = newQuantileSketch(samplingEpsilon)
+	}
+	return &SamplingHook{
+		Factor:    factor,
+		lastFired: make(map[Level]time.Time),
+		sketches:  sketches,
+	}
+}
+
+func (h *SamplingHook) Fire(e Entry) error {
+	lv := e.EntryLevel()
+	now := time.Now()
+
+	h.mu.Lock()
+	last, seen := h.lastFired[lv]
+	h.lastFired[lv] = now
+	h.mu.Unlock()
+
+	if !seen {
+		return nil
+	}
+
+	interval := now.Sub(last).Seconds()
+	sketch := h.sketches[lv]
+	p99 := sketch.Query(0.99)
+	sketch.Insert(interval)
+
+	if p99 > 0 && interval < p99/h.Factor {
+		return ErrDropEntry
+	}
+	return nil
+}
+
+// Query returns the current phi-quantile of inter-arrival duration, in
+// seconds, tracked for lv.
+func (h *SamplingHook) Query(lv Level, phi float64) float64 {
+	return h.sketches[lv].Query(phi)
+}
+
+// RegisterSamplingHook adds h as a PRE hook across every Level.
+func RegisterSamplingHook(l Logger, h *SamplingHook) {
+	for _, lv := range Levels {
+		l.AddHook(PRE, lv, h)
+	}
+}